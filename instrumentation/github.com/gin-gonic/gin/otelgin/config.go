@@ -0,0 +1,230 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelgin // import "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// config is used to configure the gin middleware.
+type config struct {
+	TracerProvider trace.TracerProvider
+	Propagators    propagation.TextMapPropagator
+	MeterProvider  metric.MeterProvider
+
+	SpanStartOptions []trace.SpanStartOption
+	SpanEndOptions   []trace.SpanEndOption
+
+	// CapturedRequestHeaders and CapturedResponseHeaders hold the list of
+	// header names (case-insensitive) that should be recorded as span
+	// attributes on the server span.
+	CapturedRequestHeaders  []string
+	CapturedResponseHeaders []string
+
+	// MetricAttributesFn, when set, is called for every request to add
+	// custom attributes to the RED metrics recorded by Middleware.
+	MetricAttributesFn func(*gin.Context) []attribute.KeyValue
+
+	// SpanNameFormatter, when set, overrides how the server span name is
+	// derived from the request. It defaults to the matched route template.
+	SpanNameFormatter func(*gin.Context) string
+
+	// AttributesFn, when set, is called for every request to add custom
+	// attributes to the server span.
+	AttributesFn func(*gin.Context) []attribute.KeyValue
+
+	// Filters and GinFilters are consulted, in order, before creating a
+	// span for a request. They compose with AND semantics: if any filter
+	// returns false, the request is not traced.
+	Filters    []func(*http.Request) bool
+	GinFilters []func(*gin.Context) bool
+
+	// ErrorStatusFn, when set, decides the span status code and description
+	// for each error recorded via c.Error(...). It defaults to marking the
+	// span as an error for every gin error except gin.ErrorTypePublic.
+	ErrorStatusFn func(*gin.Context, *gin.Error) (codes.Code, string)
+
+	// BaggageAttributeKeys lists the W3C Baggage members that are copied
+	// onto the server span as baggage.<key> attributes.
+	BaggageAttributeKeys []string
+
+	// MaxBaggageValueLength bounds how many bytes of a baggage member's
+	// value are copied onto the span; longer values are dropped entirely.
+	// Defaults to defaultMaxBaggageValueLength.
+	MaxBaggageValueLength int
+}
+
+// Option specifies instrumentation configuration options.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithPropagators specifies propagators to use for extracting information
+// from the HTTP requests. If none are specified, global ones will be used.
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return optionFunc(func(cfg *config) {
+		if propagators != nil {
+			cfg.Propagators = propagators
+		}
+	})
+}
+
+// WithTracerProvider specifies a tracer provider to use for creating a
+// tracer. If none is specified, the global provider is used.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return optionFunc(func(cfg *config) {
+		if provider != nil {
+			cfg.TracerProvider = provider
+		}
+	})
+}
+
+// WithSpanStartOptions configures an additional set of trace.SpanStartOption,
+// which are applied to each new span.
+func WithSpanStartOptions(opts ...trace.SpanStartOption) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.SpanStartOptions = append(cfg.SpanStartOptions, opts...)
+	})
+}
+
+// WithSpanEndOptions configures an additional set of trace.SpanEndOption,
+// which are applied to each span at the end of the request.
+func WithSpanEndOptions(opts ...trace.SpanEndOption) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.SpanEndOptions = append(cfg.SpanEndOptions, opts...)
+	})
+}
+
+// WithCapturedRequestHeaders configures a list of header names whose values
+// will be recorded as span attributes on the server span, following the
+// pattern http.request.header.<name>. Header name lookups are
+// case-insensitive.
+func WithCapturedRequestHeaders(headers []string) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.CapturedRequestHeaders = headers
+	})
+}
+
+// WithMeterProvider specifies a meter provider to use for creating a meter.
+// If none is specified, the global provider is used. The meter is used to
+// record the http.server.request.duration, http.server.active_requests,
+// http.server.request.body.size and http.server.response.body.size metrics.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return optionFunc(func(cfg *config) {
+		if provider != nil {
+			cfg.MeterProvider = provider
+		}
+	})
+}
+
+// WithMetricAttributesFn adds a function that, for every request, returns a
+// list of additional attributes to record against the HTTP server metrics.
+func WithMetricAttributesFn(fn func(*gin.Context) []attribute.KeyValue) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.MetricAttributesFn = fn
+	})
+}
+
+// WithSpanNameFormatter specifies a function that derives the server span
+// name from the request. If none is specified, the matched route template
+// is used, falling back to "HTTP <method> route not found" for unmatched
+// routes.
+func WithSpanNameFormatter(f func(*gin.Context) string) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.SpanNameFormatter = f
+	})
+}
+
+// WithAttributesFn adds a function that, for every request, returns a list
+// of additional attributes to set on the server span.
+func WithAttributesFn(f func(*gin.Context) []attribute.KeyValue) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.AttributesFn = f
+	})
+}
+
+// WithFilter adds a filter that is consulted, against the raw *http.Request,
+// before creating a span for a request. If f returns false, no span is
+// created for that request, though the incoming trace context is still
+// propagated. Multiple filters, gin or otherwise, compose with AND
+// semantics.
+func WithFilter(f func(*http.Request) bool) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.Filters = append(cfg.Filters, f)
+	})
+}
+
+// WithGinFilter behaves like WithFilter but gives the filter access to the
+// *gin.Context, e.g. to filter on the matched route.
+func WithGinFilter(f func(*gin.Context) bool) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.GinFilters = append(cfg.GinFilters, f)
+	})
+}
+
+// WithErrorStatusFn specifies a function that decides the span status code
+// and description for each error recorded via c.Error(...), overriding the
+// default of marking the span as codes.Error for every gin error except
+// gin.ErrorTypePublic (which leaves the span status as derived from the
+// HTTP status code).
+func WithErrorStatusFn(f func(*gin.Context, *gin.Error) (codes.Code, string)) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.ErrorStatusFn = f
+	})
+}
+
+// WithBaggageAttributes specifies which members of the incoming W3C Baggage
+// should be copied onto the server span, as baggage.<key> attributes.
+// Baggage is read from the context after propagator extraction and before
+// the span is started; members that are absent, or whose value exceeds the
+// configured max length (see WithMaxBaggageValueLength), are skipped.
+func WithBaggageAttributes(keys ...string) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.BaggageAttributeKeys = append(cfg.BaggageAttributeKeys, keys...)
+	})
+}
+
+// WithMaxBaggageValueLength overrides the default maximum length, in bytes,
+// of a baggage member value that may be copied onto the server span by
+// WithBaggageAttributes. Longer values are dropped rather than truncated.
+func WithMaxBaggageValueLength(n int) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.MaxBaggageValueLength = n
+	})
+}
+
+// WithCapturedResponseHeaders configures a list of header names whose values
+// will be recorded as span attributes on the server span, following the
+// pattern http.response.header.<name>. Header name lookups are
+// case-insensitive and the values are captured after the handler has run.
+func WithCapturedResponseHeaders(headers []string) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.CapturedResponseHeaders = headers
+	})
+}
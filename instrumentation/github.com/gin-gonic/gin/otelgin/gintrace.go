@@ -0,0 +1,244 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Based on https://github.com/DataDog/dd-trace-go/blob/8fb554ff7cf694267f9077ae35e27ce4689ed8b6/contrib/gin-gonic/gin/gintrace.go
+
+package otelgin // import "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerKey  = "otel-go-contrib-tracer-gin"
+	tracerName = "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	// defaultMaxBaggageValueLength is the default for config.MaxBaggageValueLength.
+	defaultMaxBaggageValueLength = 1024
+)
+
+// Middleware returns middleware that will trace incoming requests. The
+// service parameter should describe the name of the (virtual) server
+// handling the request.
+func Middleware(service string, opts ...Option) gin.HandlerFunc {
+	cfg := config{}
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	if cfg.TracerProvider == nil {
+		cfg.TracerProvider = otel.GetTracerProvider()
+	}
+	tracer := cfg.TracerProvider.Tracer(
+		tracerName,
+		oteltrace.WithInstrumentationVersion(SemVersion()),
+	)
+	if cfg.Propagators == nil {
+		cfg.Propagators = otel.GetTextMapPropagator()
+	}
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+	meter := cfg.MeterProvider.Meter(
+		tracerName,
+		metric.WithInstrumentationVersion(SemVersion()),
+	)
+	metrics := newInstruments(meter)
+
+	spanNameFormatter := cfg.SpanNameFormatter
+	if spanNameFormatter == nil {
+		spanNameFormatter = defaultSpanNameFormatter
+	}
+	errorStatusFn := cfg.ErrorStatusFn
+	if errorStatusFn == nil {
+		errorStatusFn = defaultErrorStatusFn
+	}
+	maxBaggageValueLength := cfg.MaxBaggageValueLength
+	if maxBaggageValueLength <= 0 {
+		maxBaggageValueLength = defaultMaxBaggageValueLength
+	}
+
+	return func(c *gin.Context) {
+		savedCtx := c.Request.Context()
+		defer func() {
+			c.Request = c.Request.WithContext(savedCtx)
+		}()
+
+		// RED metrics are recorded for every request regardless of whether
+		// it is traced: filters (see WithFilter/WithGinFilter) only decide
+		// span creation, not metrics emission.
+		start := time.Now()
+		activeAttrs := activeRequestAttributes(service, c)
+		metrics.activeRequests.Add(savedCtx, 1, metric.WithAttributes(activeAttrs...))
+		defer metrics.activeRequests.Add(savedCtx, -1, metric.WithAttributes(activeAttrs...))
+		defer func() {
+			metricAttrs := metric.WithAttributes(requestMetricAttributes(service, c, cfg.MetricAttributesFn)...)
+			metrics.requestDuration.Record(c.Request.Context(), time.Since(start).Seconds(), metricAttrs)
+			if reqSize := c.Request.ContentLength; reqSize > 0 {
+				metrics.requestBodySize.Record(c.Request.Context(), reqSize, metricAttrs)
+			}
+			if respSize := c.Writer.Size(); respSize > 0 {
+				metrics.responseBodySize.Record(c.Request.Context(), int64(respSize), metricAttrs)
+			}
+		}()
+
+		if !shouldTrace(c, cfg.Filters, cfg.GinFilters) {
+			ctx := cfg.Propagators.Extract(savedCtx, propagation.HeaderCarrier(c.Request.Header))
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			return
+		}
+
+		c.Set(tracerKey, tracer)
+
+		ctx := cfg.Propagators.Extract(savedCtx, propagation.HeaderCarrier(c.Request.Header))
+
+		spanStartOpts := []oteltrace.SpanStartOption{
+			oteltrace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(service, c.FullPath(), c.Request)...),
+			oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+		}
+		spanStartOpts = append(spanStartOpts, cfg.SpanStartOptions...)
+		spanStartOpts = append(spanStartOpts, oteltrace.WithAttributes(requestHeaderAttributes(c, cfg.CapturedRequestHeaders)...))
+		spanStartOpts = append(spanStartOpts, oteltrace.WithAttributes(baggageAttributes(ctx, cfg.BaggageAttributeKeys, maxBaggageValueLength)...))
+		if cfg.AttributesFn != nil {
+			spanStartOpts = append(spanStartOpts, oteltrace.WithAttributes(cfg.AttributesFn(c)...))
+		}
+
+		spanName := spanNameFormatter(c)
+
+		ctx, span := tracer.Start(ctx, spanName, spanStartOpts...)
+		defer span.End(cfg.SpanEndOptions...)
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := semconv.HTTPAttributesFromHTTPStatusCode(status)
+		spanStatus, spanMessage := semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, oteltrace.SpanKindServer)
+		span.SetAttributes(attrs...)
+		span.SetStatus(spanStatus, spanMessage)
+		span.SetAttributes(responseHeaderAttributes(c, cfg.CapturedResponseHeaders)...)
+
+		if len(c.Errors) > 0 {
+			span.SetAttributes(attribute.String("gin.errors", c.Errors.String()))
+		}
+		for _, err := range c.Errors {
+			span.RecordError(err, oteltrace.WithStackTrace(true))
+			if code, desc := errorStatusFn(c, err); code != codes.Unset {
+				span.SetStatus(code, desc)
+			}
+		}
+	}
+}
+
+// baggageAttributes copies the named members of the W3C Baggage carried by
+// ctx onto the span as baggage.<key> attributes. Members that are absent or
+// whose value exceeds maxValueLength bytes are skipped so that a malicious
+// or oversized baggage header cannot blow up span attribute cardinality.
+func baggageAttributes(ctx context.Context, keys []string, maxValueLength int) []attribute.KeyValue {
+	if len(keys) == 0 {
+		return nil
+	}
+	bag := baggage.FromContext(ctx)
+	attrs := make([]attribute.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		member := bag.Member(key)
+		if member.Key() == "" {
+			continue // not present in the incoming baggage
+		}
+		if value := member.Value(); len(value) <= maxValueLength {
+			attrs = append(attrs, attribute.String("baggage."+key, value))
+		}
+	}
+	return attrs
+}
+
+// defaultErrorStatusFn marks the span as failed for every gin error except
+// gin.ErrorTypePublic, which is treated as a user-facing message rather than
+// a span failure and leaves the existing (HTTP-status-derived) span status
+// untouched.
+func defaultErrorStatusFn(_ *gin.Context, err *gin.Error) (codes.Code, string) {
+	if err.Type == gin.ErrorTypePublic {
+		return codes.Unset, ""
+	}
+	return codes.Error, err.Error()
+}
+
+// shouldTrace reports whether a span should be created for c, based on the
+// configured filters. Filters compose with AND semantics: the request is
+// traced only if every filter returns true.
+func shouldTrace(c *gin.Context, filters []func(*http.Request) bool, ginFilters []func(*gin.Context) bool) bool {
+	for _, f := range filters {
+		if !f(c.Request) {
+			return false
+		}
+	}
+	for _, f := range ginFilters {
+		if !f(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultSpanNameFormatter names the span after the matched route template,
+// falling back to an explicit "route not found" name so that unmatched
+// requests don't inflate span-name cardinality with raw paths.
+func defaultSpanNameFormatter(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return fmt.Sprintf("HTTP %s route not found", c.Request.Method)
+}
+
+// requestHeaderAttributes builds the http.request.header.<name> span
+// attributes for the headers named in captured. Header lookups are
+// case-insensitive, matching net/http.Header semantics.
+func requestHeaderAttributes(c *gin.Context, captured []string) []attribute.KeyValue {
+	return headerAttributes("http.request.header.", c.Request.Header, captured)
+}
+
+// responseHeaderAttributes builds the http.response.header.<name> span
+// attributes for the headers named in captured. It must be called after
+// c.Next() so that handler-set response headers are visible.
+func responseHeaderAttributes(c *gin.Context, captured []string) []attribute.KeyValue {
+	return headerAttributes("http.response.header.", c.Writer.Header(), captured)
+}
+
+func headerAttributes(prefix string, header http.Header, captured []string) []attribute.KeyValue {
+	if len(captured) == 0 {
+		return nil
+	}
+	attrs := make([]attribute.KeyValue, 0, len(captured))
+	for _, name := range captured {
+		if values := header.Values(name); len(values) > 0 {
+			attrs = append(attrs, attribute.StringSlice(prefix+strings.ToLower(name), values))
+		}
+	}
+	return attrs
+}
@@ -18,6 +18,7 @@ package otelgin
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -25,9 +26,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
@@ -183,3 +187,252 @@ func TestWithCustomSpanEndOptions(t *testing.T) {
 	// check for span end time
 	assert.Equal(t, spans[0].EndTime(), spanEndTime, "Span end time should be equal to overrided one")
 }
+
+func TestWithCapturedRequestHeaders(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	r.Header.Set("X-Request-Id", "abc-123")
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar", WithTracerProvider(provider), WithCapturedRequestHeaders([]string{"X-Request-Id"})))
+	router.GET("/user/:id", func(c *gin.Context) {})
+
+	router.ServeHTTP(w, r)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+
+	attr, ok := findAttribute(spans[0].Attributes(), "http.request.header.x-request-id")
+	require.True(t, ok, "expected http.request.header.x-request-id attribute")
+	assert.Equal(t, []string{"abc-123"}, attr.Value.AsStringSlice())
+}
+
+func TestWithCapturedResponseHeaders(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar", WithTracerProvider(provider), WithCapturedResponseHeaders([]string{"X-Cache-Status"})))
+	router.GET("/user/:id", func(c *gin.Context) {
+		c.Header("X-Cache-Status", "HIT")
+	})
+
+	router.ServeHTTP(w, r)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+
+	attr, ok := findAttribute(spans[0].Attributes(), "http.response.header.x-cache-status")
+	require.True(t, ok, "expected http.response.header.x-cache-status attribute")
+	assert.Equal(t, []string{"HIT"}, attr.Value.AsStringSlice())
+}
+
+func TestWithSpanNameFormatter(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar", WithTracerProvider(provider), WithSpanNameFormatter(func(c *gin.Context) string {
+		return c.Request.Method + " " + c.FullPath()
+	})))
+	router.GET("/user/:id", func(c *gin.Context) {})
+
+	router.ServeHTTP(w, r)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /user/:id", spans[0].Name())
+}
+
+func TestWithAttributesFn(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar", WithTracerProvider(provider), WithAttributesFn(func(c *gin.Context) []attribute.KeyValue {
+		return []attribute.KeyValue{attribute.String("tenant.id", "acme")}
+	})))
+	router.GET("/user/:id", func(c *gin.Context) {})
+
+	router.ServeHTTP(w, r)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+
+	attr, ok := findAttribute(spans[0].Attributes(), "tenant.id")
+	require.True(t, ok, "expected tenant.id attribute")
+	assert.Equal(t, "acme", attr.Value.AsString())
+}
+
+func TestWithFilter(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	r := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	var sawRequest *http.Request
+	router := gin.New()
+	router.Use(Middleware("foobar", WithTracerProvider(provider), WithFilter(func(r *http.Request) bool {
+		sawRequest = r
+		return r.URL.Path != "/health"
+	})))
+	router.GET("/health", func(c *gin.Context) {
+		span := trace.SpanFromContext(c.Request.Context())
+		assert.False(t, span.SpanContext().IsValid())
+	})
+
+	router.ServeHTTP(w, r)
+
+	assert.Empty(t, spanRecorder.Ended(), "filtered request should not produce a span")
+	assert.Same(t, r, sawRequest, "filter should see the raw *http.Request")
+}
+
+func TestWithGinFilterComposesWithAND(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	r := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar",
+		WithTracerProvider(provider),
+		WithFilter(func(r *http.Request) bool { return true }),
+		WithGinFilter(func(c *gin.Context) bool { return c.FullPath() != "/metrics" }),
+	))
+	router.GET("/metrics", func(c *gin.Context) {})
+
+	router.ServeHTTP(w, r)
+
+	assert.Empty(t, spanRecorder.Ended(), "filtered request should not produce a span")
+}
+
+func TestMiddlewareRecordsGinErrors(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar", WithTracerProvider(provider)))
+	router.GET("/user/:id", func(c *gin.Context) {
+		_ = c.Error(errors.New("boom"))
+		c.String(http.StatusOK, "ok")
+	})
+
+	router.ServeHTTP(w, r)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+
+	events := spans[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "exception", events[0].Name)
+
+	status := spans[0].Status()
+	assert.Equal(t, codes.Error, status.Code)
+}
+
+func TestDefaultErrorStatusFnPublicErrorsDoNotFailSpan(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar", WithTracerProvider(provider)))
+	router.GET("/user/:id", func(c *gin.Context) {
+		_ = c.Error(errors.New("validation warning")).SetType(gin.ErrorTypePublic)
+		c.String(http.StatusOK, "ok")
+	})
+
+	router.ServeHTTP(w, r)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+
+	status := spans[0].Status()
+	assert.NotEqual(t, codes.Error, status.Code)
+}
+
+func TestWithBaggageAttributes(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.Baggage{})
+
+	member, err := baggage.NewMember("user.id", "42")
+	require.NoError(t, err)
+	bag, err := baggage.New(member)
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	propagator.Inject(baggage.ContextWithBaggage(context.Background(), bag), propagation.HeaderCarrier(r.Header))
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar", WithTracerProvider(provider), WithPropagators(propagator), WithBaggageAttributes("user.id", "tenant")))
+	router.GET("/user/:id", func(c *gin.Context) {})
+
+	router.ServeHTTP(w, r)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+
+	attr, ok := findAttribute(spans[0].Attributes(), "baggage.user.id")
+	require.True(t, ok, "expected baggage.user.id attribute")
+	assert.Equal(t, "42", attr.Value.AsString())
+
+	_, ok = findAttribute(spans[0].Attributes(), "baggage.tenant")
+	assert.False(t, ok, "absent baggage member should not produce an attribute")
+}
+
+func TestWithBaggageAttributesSkipsOversizedValues(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.Baggage{})
+
+	member, err := baggage.NewMember("user.id", "too-long-a-value")
+	require.NoError(t, err)
+	bag, err := baggage.New(member)
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	propagator.Inject(baggage.ContextWithBaggage(context.Background(), bag), propagation.HeaderCarrier(r.Header))
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar", WithTracerProvider(provider), WithPropagators(propagator), WithBaggageAttributes("user.id"), WithMaxBaggageValueLength(4)))
+	router.GET("/user/:id", func(c *gin.Context) {})
+
+	router.ServeHTTP(w, r)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+
+	_, ok := findAttribute(spans[0].Attributes(), "baggage.user.id")
+	assert.False(t, ok, "oversized baggage value should be skipped")
+}
+
+func findAttribute(attrs []attribute.KeyValue, key string) (attribute.KeyValue, bool) {
+	for _, attr := range attrs {
+		if string(attr.Key) == key {
+			return attr, true
+		}
+	}
+	return attribute.KeyValue{}, false
+}
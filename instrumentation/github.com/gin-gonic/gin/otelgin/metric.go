@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelgin // import "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+// instruments holds the RED-style HTTP server metrics recorded by Middleware.
+type instruments struct {
+	requestDuration  metric.Float64Histogram
+	requestBodySize  metric.Int64Histogram
+	responseBodySize metric.Int64Histogram
+	activeRequests   metric.Int64UpDownCounter
+}
+
+// newInstruments creates the otelgin HTTP server metrics on meter. Creation
+// errors are handed to otel.Handle and the affected instrument falls back to
+// a no-op implementation, so that a misconfigured MeterProvider degrades
+// metrics collection instead of panicking on every request.
+func newInstruments(meter metric.Meter) *instruments {
+	requestDuration, err := meter.Float64Histogram(
+		"http.server.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP server requests."),
+	)
+	if err != nil {
+		otel.Handle(err)
+		requestDuration = noop.Float64Histogram{}
+	}
+	requestBodySize, err := meter.Int64Histogram(
+		"http.server.request.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server request bodies."),
+	)
+	if err != nil {
+		otel.Handle(err)
+		requestBodySize = noop.Int64Histogram{}
+	}
+	responseBodySize, err := meter.Int64Histogram(
+		"http.server.response.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server response bodies."),
+	)
+	if err != nil {
+		otel.Handle(err)
+		responseBodySize = noop.Int64Histogram{}
+	}
+	activeRequests, err := meter.Int64UpDownCounter(
+		"http.server.active_requests",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of in-flight HTTP server requests."),
+	)
+	if err != nil {
+		otel.Handle(err)
+		activeRequests = noop.Int64UpDownCounter{}
+	}
+	return &instruments{
+		requestDuration:  requestDuration,
+		requestBodySize:  requestBodySize,
+		responseBodySize: responseBodySize,
+		activeRequests:   activeRequests,
+	}
+}
+
+// activeRequestAttributes returns the attribute set recorded against
+// http.server.active_requests. It intentionally excludes http.route and
+// http.response.status_code, which are only known once routing/handling has
+// happened.
+func activeRequestAttributes(service string, c *gin.Context) []attribute.KeyValue {
+	return semconv.HTTPServerMetricAttributesFromHTTPRequest(service, c.Request)
+}
+
+// requestMetricAttributes returns the bounded-cardinality attribute set
+// shared by the per-request histograms: method, matched route template (not
+// the raw path, to keep cardinality bounded), and response status code.
+func requestMetricAttributes(service string, c *gin.Context, metricAttributesFn func(*gin.Context) []attribute.KeyValue) []attribute.KeyValue {
+	attrs := semconv.HTTPServerMetricAttributesFromHTTPRequest(service, c.Request)
+	attrs = append(attrs,
+		semconv.HTTPRouteKey.String(c.FullPath()),
+		semconv.HTTPStatusCodeKey.Int(c.Writer.Status()),
+	)
+	if metricAttributesFn != nil {
+		attrs = append(attrs, metricAttributesFn(c)...)
+	}
+	return attrs
+}
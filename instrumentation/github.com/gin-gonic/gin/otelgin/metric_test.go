@@ -0,0 +1,198 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelgin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMiddlewareMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	r := httptest.NewRequest("POST", "/user/123", strings.NewReader("payload"))
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar", WithMeterProvider(provider)))
+	router.POST("/user/:id", func(c *gin.Context) {
+		c.String(200, "ok")
+	})
+
+	router.ServeHTTP(w, r)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	wantAttrs := []attribute.KeyValue{
+		attribute.String("http.method", "POST"),
+		attribute.String("http.route", "/user/:id"),
+		attribute.Int("http.status_code", 200),
+	}
+
+	duration := findHistogramFloat64(t, rm, "http.server.request.duration")
+	require.Len(t, duration.DataPoints, 1, "exactly one data point, keyed by route template not raw path")
+	assertAttributesSubset(t, duration.DataPoints[0].Attributes, wantAttrs)
+	assert.GreaterOrEqual(t, duration.DataPoints[0].Sum, 0.0)
+	assert.Equal(t, uint64(1), duration.DataPoints[0].Count)
+
+	reqSize := findHistogramInt64(t, rm, "http.server.request.body.size")
+	require.Len(t, reqSize.DataPoints, 1)
+	assertAttributesSubset(t, reqSize.DataPoints[0].Attributes, wantAttrs)
+	assert.Equal(t, int64(len("payload")), reqSize.DataPoints[0].Sum)
+
+	respSize := findHistogramInt64(t, rm, "http.server.response.body.size")
+	require.Len(t, respSize.DataPoints, 1)
+	assertAttributesSubset(t, respSize.DataPoints[0].Attributes, wantAttrs)
+	assert.Equal(t, int64(len("ok")), respSize.DataPoints[0].Sum)
+
+	active := findSumInt64(t, rm, "http.server.active_requests")
+	require.Len(t, active.DataPoints, 1, "up-down counter nets back to zero after the request completes")
+	assert.Equal(t, int64(0), active.DataPoints[0].Value)
+}
+
+func TestMiddlewareMetricsWithMetricAttributesFn(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar",
+		WithMeterProvider(provider),
+		WithMetricAttributesFn(func(c *gin.Context) []attribute.KeyValue {
+			return []attribute.KeyValue{attribute.String("tenant.id", c.Param("id"))}
+		}),
+	))
+	router.GET("/user/:id", func(c *gin.Context) {
+		c.String(200, "ok")
+	})
+
+	router.ServeHTTP(w, r)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	wantAttrs := []attribute.KeyValue{
+		attribute.String("http.route", "/user/:id"),
+		attribute.String("tenant.id", "123"),
+	}
+
+	duration := findHistogramFloat64(t, rm, "http.server.request.duration")
+	require.Len(t, duration.DataPoints, 1)
+	assertAttributesSubset(t, duration.DataPoints[0].Attributes, wantAttrs)
+}
+
+func TestMiddlewareMetricsRecordedForFilteredRequests(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	r := httptest.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	router := gin.New()
+	router.Use(Middleware("foobar",
+		WithMeterProvider(provider),
+		WithFilter(func(r *http.Request) bool { return r.URL.Path != "/healthz" }),
+	))
+	router.GET("/healthz", func(c *gin.Context) {
+		c.String(200, "ok")
+	})
+
+	router.ServeHTTP(w, r)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1, "filtering out the span must not filter out RED metrics")
+
+	duration := findHistogramFloat64(t, rm, "http.server.request.duration")
+	require.Len(t, duration.DataPoints, 1)
+	assert.Equal(t, uint64(1), duration.DataPoints[0].Count)
+
+	active := findSumInt64(t, rm, "http.server.active_requests")
+	require.Len(t, active.DataPoints, 1)
+	assert.Equal(t, int64(0), active.DataPoints[0].Value)
+}
+
+func assertAttributesSubset(t *testing.T, got attribute.Set, want []attribute.KeyValue) {
+	t.Helper()
+	for _, kv := range want {
+		v, ok := got.Value(kv.Key)
+		if assert.True(t, ok, "missing attribute %s", kv.Key) {
+			assert.Equal(t, kv.Value, v, "unexpected value for attribute %s", kv.Key)
+		}
+	}
+	seen := map[attribute.Key]bool{}
+	for _, kv := range got.ToSlice() {
+		assert.Falsef(t, seen[kv.Key], "duplicate attribute %s", kv.Key)
+		seen[kv.Key] = true
+	}
+}
+
+func findHistogramFloat64(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Histogram[float64] {
+	t.Helper()
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != name {
+			continue
+		}
+		h, ok := m.Data.(metricdata.Histogram[float64])
+		require.Truef(t, ok, "%s is not a float64 histogram", name)
+		return h
+	}
+	t.Fatalf("metric %s not found", name)
+	return metricdata.Histogram[float64]{}
+}
+
+func findHistogramInt64(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Histogram[int64] {
+	t.Helper()
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != name {
+			continue
+		}
+		h, ok := m.Data.(metricdata.Histogram[int64])
+		require.Truef(t, ok, "%s is not an int64 histogram", name)
+		return h
+	}
+	t.Fatalf("metric %s not found", name)
+	return metricdata.Histogram[int64]{}
+}
+
+func findSumInt64(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Sum[int64] {
+	t.Helper()
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != name {
+			continue
+		}
+		s, ok := m.Data.(metricdata.Sum[int64])
+		require.Truef(t, ok, "%s is not an int64 sum", name)
+		return s
+	}
+	t.Fatalf("metric %s not found", name)
+	return metricdata.Sum[int64]{}
+}